@@ -0,0 +1,129 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package thrift
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"sort"
+	"time"
+
+	"go.uber.org/yarpc/api/transport"
+)
+
+// Cache stores encoded Thrift reply frames keyed by a digest of the
+// request that produced them. The interface is deliberately small so
+// that Redis, Memcached, or other out-of-process stores can implement it
+// without depending on anything else in this package.
+type Cache interface {
+	// Get returns the cached reply frame for key, if present and not
+	// expired.
+	Get(ctx context.Context, key string) (reply []byte, ok bool)
+
+	// Set stores reply under key for ttl. A zero ttl means the entry
+	// never expires.
+	Set(ctx context.Context, key string, reply []byte, ttl time.Duration)
+}
+
+// CachePolicy configures what a Client caches and for how long.
+type CachePolicy struct {
+	// DefaultTTL is the cache lifetime for procedures with no entry in
+	// PerProcedureTTL. Zero means such procedures are not cached.
+	DefaultTTL time.Duration
+
+	// PerProcedureTTL overrides DefaultTTL for specific procedures,
+	// keyed by the same "Service::method" name that appears in
+	// transport.Request.Procedure.
+	PerProcedureTTL map[string]time.Duration
+
+	// NegativeTTL, if non-zero, also caches replies that decode to a
+	// Thrift exception, so a procedure that is failing doesn't get
+	// called again on every request until NegativeTTL elapses.
+	NegativeTTL time.Duration
+
+	// HeaderKeys lists the transport.Request headers, if any, that
+	// distinguish otherwise-identical requests for caching purposes
+	// (e.g. a tenant ID). Headers not listed here do not affect the
+	// cache key, so two requests differing only in an unlisted header
+	// share a cache entry.
+	HeaderKeys []string
+
+	// SingleFlight coalesces concurrent Calls that share a cache key so
+	// that only one of them reaches the outbound; the rest block on its
+	// result. This bounds the "thundering herd" that would otherwise
+	// reach the callee while a cache entry is being populated.
+	//
+	// This package imports golang.org/x/sync/singleflight for it, whether
+	// or not it is enabled; this repo slice has no go.mod/go.sum to pin it
+	// in, so record the requirement here until a manifest exists.
+	SingleFlight bool
+}
+
+// ttl returns the cache lifetime configured for procedure, and whether
+// procedure should be cached at all.
+func (p CachePolicy) ttl(procedure string) (ttl time.Duration, ok bool) {
+	if ttl, ok := p.PerProcedureTTL[procedure]; ok {
+		return ttl, true
+	}
+	if p.DefaultTTL > 0 {
+		return p.DefaultTTL, true
+	}
+	return 0, false
+}
+
+// WithCache enables client-side response caching. cache stores encoded
+// replies; policy decides what gets cached, for how long, and whether
+// concurrent identical calls are coalesced.
+//
+// Caching only applies to Call; CallOneway has no reply to cache.
+func WithCache(cache Cache, policy CachePolicy) ClientOption {
+	return clientOptionFunc(func(c *clientConfig) {
+		c.Cache = cache
+		c.CachePolicy = policy
+	})
+}
+
+// cacheKey digests the parts of a request that determine its reply: the
+// service, the procedure, the header subset named by policy.HeaderKeys,
+// and the encoded body. Two requests that hash to the same key are
+// assumed to produce the same reply.
+func cacheKey(treq *transport.Request, policy CachePolicy, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, treq.Service)
+	h.Write([]byte{0})
+	io.WriteString(h, treq.Procedure)
+	h.Write([]byte{0})
+
+	keys := append([]string(nil), policy.HeaderKeys...)
+	sort.Strings(keys)
+	items := treq.Headers.Items()
+	for _, k := range keys {
+		io.WriteString(h, k)
+		h.Write([]byte{'='})
+		io.WriteString(h, items[k])
+		h.Write([]byte{0})
+	}
+
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package thrift
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCachePolicyTTL(t *testing.T) {
+	policy := CachePolicy{
+		DefaultTTL: time.Minute,
+		PerProcedureTTL: map[string]time.Duration{
+			"GetWidget": 5 * time.Minute,
+		},
+	}
+
+	if ttl, ok := policy.ttl("GetWidget"); !ok || ttl != 5*time.Minute {
+		t.Fatalf("ttl(%q) = %v, %v; want %v, true", "GetWidget", ttl, ok, 5*time.Minute)
+	}
+	if ttl, ok := policy.ttl("ListWidgets"); !ok || ttl != time.Minute {
+		t.Fatalf("ttl(%q) = %v, %v; want %v, true", "ListWidgets", ttl, ok, time.Minute)
+	}
+}
+
+func TestCachePolicyTTLNotCacheableByDefault(t *testing.T) {
+	var policy CachePolicy
+
+	if ttl, ok := policy.ttl("GetWidget"); ok {
+		t.Fatalf("ttl(%q) = %v, true; want ok=false for a zero-value policy", "GetWidget", ttl)
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package thrift
+
+import (
+	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/pkg/tracing"
+)
+
+// thriftSystem is recorded as the rpc.system attribute on every span
+// started by RegisterTracing and by a client built with WithTracer.
+const thriftSystem = "thrift"
+
+// RegisterTracing installs tracer as inbound tracing middleware on cfg,
+// composing with whatever inbound middleware cfg already carries. The
+// middleware runs for every unary inbound request of the dispatcher, not
+// only Thrift ones, and tags each of its spans rpc.system=thrift. Call it
+// before passing cfg to yarpc.NewDispatcher:
+//
+//	cfg := yarpc.Config{Name: "my-service"}
+//	thrift.RegisterTracing(&cfg, tracer)
+//	dispatcher := yarpc.NewDispatcher(cfg)
+//
+// This is the Thrift-encoding counterpart to WithTracer, which
+// instruments outbound Calls; RegisterTracing instruments inbound
+// handlers the same way.
+func RegisterTracing(cfg *yarpc.Config, tracer *tracing.Tracer) {
+	tracing.Register(cfg, tracer, thriftSystem)
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package thrift
+
+import (
+	"fmt"
+
+	"go.uber.org/thriftrw/protocol"
+	"go.uber.org/yarpc/encoding/thrift/protocols"
+	"go.uber.org/yarpc/pkg/tracing"
+)
+
+// ClientOption customizes the behavior of a Thrift client.
+type ClientOption interface {
+	applyClientOption(*clientConfig)
+}
+
+// clientConfig accumulates the options applied to a Client constructed via
+// New.
+type clientConfig struct {
+	Protocol    protocol.Protocol
+	Multiplexed bool
+	Enveloping  bool
+	Tracer      *tracing.Tracer
+	Cache       Cache
+	CachePolicy CachePolicy
+}
+
+type clientOptionFunc func(*clientConfig)
+
+func (f clientOptionFunc) applyClientOption(c *clientConfig) { f(c) }
+
+// Multiplexed specifies whether the Thrift client should send requests
+// using the multiplexed protocol, which prefixes the method name with the
+// name of the Thrift service.
+//
+// This is usually only necessary to talk to Apache Thrift services that
+// expose multiple services on the same connection.
+func Multiplexed(multiplexed bool) ClientOption {
+	return clientOptionFunc(func(c *clientConfig) {
+		c.Multiplexed = multiplexed
+	})
+}
+
+// Enveloped specifies whether requests and responses should be wrapped in
+// Thrift envelopes. This defaults to false.
+func Enveloped(enveloped bool) ClientOption {
+	return clientOptionFunc(func(c *clientConfig) {
+		c.Enveloping = enveloped
+	})
+}
+
+// Protocol changes the Thrift protocol used by this client. This is
+// Binary by default.
+func Protocol(p protocol.Protocol) ClientOption {
+	return clientOptionFunc(func(c *clientConfig) {
+		c.Protocol = p
+	})
+}
+
+// ProtocolName is like Protocol but looks the protocol up by name in the
+// protocols.Default registry, so that YAML-driven configuration can say
+//
+//	protocol: header
+//
+// instead of requiring Go code to import a specific
+// go.uber.org/thriftrw/protocol package. It panics if name is not
+// registered; config loaders should validate name against
+// protocols.Lookup before constructing the client.
+func ProtocolName(name string) ClientOption {
+	return clientOptionFunc(func(c *clientConfig) {
+		p, ok := protocols.Lookup(name)
+		if !ok {
+			panic(fmt.Sprintf("thrift: no protocol registered under %q", name))
+		}
+		c.Protocol = p
+	})
+}
+
+// WithTracer instruments the client so that every outbound call is wrapped
+// in an OpenTelemetry span created from the given Tracer, and so that the
+// span context is propagated to the callee.
+//
+// Without this option, Call and CallOneway do not emit any tracing spans
+// and behave exactly as before.
+func WithTracer(tracer *tracing.Tracer) ClientOption {
+	return clientOptionFunc(func(c *clientConfig) {
+		c.Tracer = tracer
+	})
+}
@@ -24,6 +24,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"time"
 
 	"go.uber.org/thriftrw/envelope"
 	"go.uber.org/thriftrw/protocol"
@@ -32,9 +33,12 @@ import (
 	encodingapi "go.uber.org/yarpc/api/encoding"
 	"go.uber.org/yarpc/api/transport"
 	"go.uber.org/yarpc/encoding/thrift/internal"
+	"go.uber.org/yarpc/encoding/thrift/protocols"
 	"go.uber.org/yarpc/pkg/encoding"
 	"go.uber.org/yarpc/pkg/errors"
 	"go.uber.org/yarpc/pkg/procedure"
+	"go.uber.org/yarpc/pkg/tracing"
+	"golang.org/x/sync/singleflight"
 )
 
 // Client is a generic Thrift client. It speaks in raw Thrift payloads.
@@ -47,6 +51,24 @@ type Client interface {
 	CallOneway(ctx context.Context, reqBody envelope.Enveloper, opts ...yarpc.CallOption) (transport.Ack, error)
 }
 
+// StreamingClient is implemented by every Client that New returns;
+// its CallStream fails unless the client was built with
+// Config.StreamingEnabled. Callers that need CallStream should type-assert
+// for it rather than requiring it on every Client:
+//
+//	streamer, ok := client.(thrift.StreamingClient)
+//
+// Adding CallStream to Client directly would break any existing mock or
+// fake implementing Client, since those don't know about streaming.
+type StreamingClient interface {
+	Client
+
+	// CallStream opens a long-lived, bidirectional Thrift interaction
+	// with the named method. It requires Config.StreamingEnabled and a
+	// transport that supports streaming.
+	CallStream(ctx context.Context, method string, opts ...yarpc.CallOption) (ClientStream, error)
+}
+
 // Config contains the configuration for the Client.
 type Config struct {
 	// Name of the Thrift service. This is the name used in the Thrift file
@@ -55,6 +77,17 @@ type Config struct {
 
 	// ClientConfig through which requests will be sent. Required.
 	ClientConfig transport.ClientConfig
+
+	// StreamingEnabled opts this client into CallStream. It requires a
+	// transport that supports streaming (HTTP/2, TChannel framed).
+	//
+	// This is a local opt-in only: it is not negotiated with
+	// the peer. serialize/internal's Features.supportsStreaming IDL field
+	// describes a peer's capabilities for callers that generate and wire up
+	// their own accessors for it; this package does not read or write that
+	// field, so setting StreamingEnabled does not by itself confirm the
+	// callee can handle a stream.
+	StreamingEnabled bool
 }
 
 // New creates a new Thrift client.
@@ -91,11 +124,21 @@ func New(c Config, opts ...ClientOption) Client {
 		}
 	}
 
+	var single *singleflight.Group
+	if cc.Cache != nil && cc.CachePolicy.SingleFlight {
+		single = new(singleflight.Group)
+	}
+
 	return thriftClient{
-		p:             p,
-		cc:            c.ClientConfig,
-		thriftService: c.Service,
-		Enveloping:    cc.Enveloping,
+		p:                p,
+		cc:               c.ClientConfig,
+		thriftService:    c.Service,
+		Enveloping:       cc.Enveloping,
+		tracer:           cc.Tracer,
+		streamingEnabled: c.StreamingEnabled,
+		cache:            cc.Cache,
+		cachePolicy:      cc.CachePolicy,
+		single:           single,
 	}
 }
 
@@ -106,9 +149,55 @@ type thriftClient struct {
 	// name of the Thrift service
 	thriftService string
 	Enveloping    bool
+
+	// tracer, when non-nil, wraps Call and CallOneway in an OpenTelemetry
+	// span and propagates it to the callee. It is nil unless the client
+	// was built with WithTracer.
+	tracer *tracing.Tracer
+
+	// streamingEnabled reports whether this client was built with
+	// Config.StreamingEnabled, and therefore may call CallStream.
+	streamingEnabled bool
+
+	// cache, when non-nil, is consulted by Call before every outbound
+	// request and populated with its reply, per cachePolicy. It is nil
+	// unless the client was built with WithCache.
+	cache       Cache
+	cachePolicy CachePolicy
+
+	// single, when non-nil, coalesces concurrent Calls that share a
+	// cache key into a single outbound request. It is nil unless the
+	// client was built with WithCache and CachePolicy.SingleFlight.
+	single *singleflight.Group
+}
+
+// startSpan starts an outbound span for reqBody if c.tracer is configured,
+// injects the resulting span context into treq's headers, and returns the
+// (possibly unchanged) context along with a function that must be
+// deferred to end the span.
+func (c thriftClient) startSpan(ctx context.Context, treq *transport.Request, reqBody envelope.Enveloper, envelopeType wire.EnvelopeType) (context.Context, func(err error)) {
+	if c.tracer == nil {
+		return ctx, func(error) {}
+	}
+
+	ctx, span := c.tracer.StartOutboundSpan(ctx, tracing.CallAttributes{
+		System:      thriftSystem,
+		Service:     c.thriftService,
+		Method:      reqBody.MethodName(),
+		PeerService: c.cc.Service(),
+		Envelope:    envelopeType.String(),
+	})
+	treq.Headers = tracing.InjectSpanContextHeader(treq.Headers, span.SpanContext())
+
+	return ctx, func(err error) {
+		if err != nil {
+			tracing.RecordError(span, err)
+		}
+		span.End()
+	}
 }
 
-func (c thriftClient) Call(ctx context.Context, reqBody envelope.Enveloper, opts ...yarpc.CallOption) (wire.Value, error) {
+func (c thriftClient) Call(ctx context.Context, reqBody envelope.Enveloper, opts ...yarpc.CallOption) (result wire.Value, err error) {
 	// Code generated for Thrift client calls will probably be something like
 	// this:
 	//
@@ -125,33 +214,97 @@ func (c thriftClient) Call(ctx context.Context, reqBody envelope.Enveloper, opts
 
 	out := c.cc.GetUnaryOutbound()
 
-	treq, proto, err := c.buildTransportRequest(reqBody)
+	treq, proto, env, err := c.buildTransportRequest(reqBody)
 	if err != nil {
 		return wire.Value{}, err
 	}
 
+	// Captured before encodeHeaderFrame folds treq.Headers into treq.Body,
+	// so a cache digest is stable across calls even when a per-call header
+	// (e.g. startSpan's span-context header) changes every time.
+	canonicalBody := canonicalRequestBody(treq)
+
+	ctx, endSpan := c.startSpan(ctx, treq, reqBody, reqBody.EnvelopeType())
+	defer func() { endSpan(err) }()
+
 	call := encodingapi.NewOutboundCall(encoding.FromOptions(opts)...)
 	ctx, err = call.WriteToRequest(ctx, treq)
 	if err != nil {
 		return wire.Value{}, err
 	}
 
-	tres, err := out.Call(ctx, treq)
-	if err != nil && (tres == nil || !tres.ApplicationError) {
-		return wire.Value{}, err
+	if err = c.encodeHeaderFrame(treq, proto, env); err != nil {
+		return wire.Value{}, errors.RequestBodyEncodeError(treq, err)
 	}
-	defer tres.Body.Close()
 
-	if _, err = call.ReadFromResponse(ctx, tres); err != nil {
-		return wire.Value{}, err
+	key, ttl, cacheable := c.cacheKeyFor(treq, canonicalBody)
+	if cacheable {
+		if raw, ok := c.cache.Get(ctx, key); ok {
+			return c.decodeReply(proto, treq, raw)
+		}
 	}
 
-	buf := bytes.NewBuffer(make([]byte, 0, _defaultBufferSize))
-	if _, err = buf.ReadFrom(tres.Body); err != nil {
-		return wire.Value{}, err
+	fetch := func(fetchCtx context.Context) (wire.Value, error) {
+		tres, err := out.Call(fetchCtx, treq)
+		if err != nil && (tres == nil || !tres.ApplicationError) {
+			return wire.Value{}, err
+		}
+		defer tres.Body.Close()
+
+		if _, err = call.ReadFromResponse(fetchCtx, tres); err != nil {
+			return wire.Value{}, err
+		}
+
+		buf := bytes.NewBuffer(make([]byte, 0, _defaultBufferSize))
+		if _, err = buf.ReadFrom(tres.Body); err != nil {
+			return wire.Value{}, err
+		}
+
+		result, err := c.decodeReply(proto, treq, buf.Bytes())
+		if cacheable {
+			c.storeReply(fetchCtx, key, ttl, buf.Bytes(), err)
+		}
+		return result, err
+	}
+
+	if cacheable && c.single != nil {
+		// The leader's ctx would otherwise be shared by every coalesced
+		// caller: a cancellation that belongs to whichever caller happened
+		// to arrive first would fail every other caller's identical, but
+		// otherwise independent, request. Detach from that cancellation so
+		// the single outbound call isn't killed by an unrelated caller
+		// giving up. Every coalesced caller blocks until the shared call
+		// returns, whatever its own ctx does: singleflight.Do does not
+		// observe ctx cancellation.
+		//
+		// Re-apply the leader's own deadline, though: detaching it
+		// entirely would leave a slow or hung callee with no timeout at
+		// all once every coalesced caller has given up, leaking the
+		// in-flight RPC indefinitely.
+		leaderCtx := context.WithoutCancel(ctx)
+		if deadline, ok := ctx.Deadline(); ok {
+			var cancel context.CancelFunc
+			leaderCtx, cancel = context.WithDeadline(leaderCtx, deadline)
+			defer cancel()
+		}
+		v, err, _ := c.single.Do(key, func() (interface{}, error) {
+			result, err := fetch(leaderCtx)
+			return result, err
+		})
+		if err != nil {
+			return wire.Value{}, err
+		}
+		return v.(wire.Value), nil
 	}
 
-	envelope, err := proto.DecodeEnveloped(bytes.NewReader(buf.Bytes()))
+	return fetch(ctx)
+}
+
+// decodeReply parses raw into the wire.Value or thriftException a
+// generated client expects, whether raw came from a live response or a
+// cache hit.
+func (c thriftClient) decodeReply(proto protocol.Protocol, treq *transport.Request, raw []byte) (wire.Value, error) {
+	envelope, err := proto.DecodeEnveloped(bytes.NewReader(raw))
 	if err != nil {
 		return wire.Value{}, errors.ResponseBodyDecodeError(treq, err)
 	}
@@ -175,24 +328,37 @@ func (c thriftClient) Call(ctx context.Context, reqBody envelope.Enveloper, opts
 	}
 }
 
-func (c thriftClient) CallOneway(ctx context.Context, reqBody envelope.Enveloper, opts ...yarpc.CallOption) (transport.Ack, error) {
+func (c thriftClient) CallOneway(ctx context.Context, reqBody envelope.Enveloper, opts ...yarpc.CallOption) (ack transport.Ack, err error) {
 	out := c.cc.GetOnewayOutbound()
 
-	treq, _, err := c.buildTransportRequest(reqBody)
+	treq, proto, env, err := c.buildTransportRequest(reqBody)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, endSpan := c.startSpan(ctx, treq, reqBody, reqBody.EnvelopeType())
+	defer func() { endSpan(err) }()
+
 	call := encodingapi.NewOutboundCall(encoding.FromOptions(opts)...)
 	ctx, err = call.WriteToRequest(ctx, treq)
 	if err != nil {
 		return nil, err
 	}
 
-	return out.CallOneway(ctx, treq)
+	if err = c.encodeHeaderFrame(treq, proto, env); err != nil {
+		return nil, errors.RequestBodyEncodeError(treq, err)
+	}
+
+	ack, err = out.CallOneway(ctx, treq)
+	return ack, err
 }
 
-func (c thriftClient) buildTransportRequest(reqBody envelope.Enveloper) (*transport.Request, protocol.Protocol, error) {
+// buildTransportRequest builds the transport.Request for reqBody, along
+// with the protocol.Protocol and wire.Envelope used to produce its Body.
+// treq.Body is already populated with the plain, headerless encoding of
+// env; encodeHeaderFrame re-encodes it once treq.Headers has its final
+// value, for protocols that carry headers inline in the frame.
+func (c thriftClient) buildTransportRequest(reqBody envelope.Enveloper) (*transport.Request, protocol.Protocol, wire.Envelope, error) {
 	proto := c.p
 	if !c.Enveloping {
 		proto = disableEnvelopingProtocol{
@@ -212,29 +378,99 @@ func (c thriftClient) buildTransportRequest(reqBody envelope.Enveloper) (*transp
 	if err != nil {
 		// ToWire validates the request. If it failed, we should return the error
 		// as-is because it's not an encoding error.
-		return nil, nil, err
+		return nil, nil, wire.Envelope{}, err
 	}
 
 	reqEnvelopeType := reqBody.EnvelopeType()
 	if reqEnvelopeType != wire.Call && reqEnvelopeType != wire.OneWay {
-		return nil, nil, errors.RequestBodyEncodeError(
+		return nil, nil, wire.Envelope{}, errors.RequestBodyEncodeError(
 			&treq, errUnexpectedEnvelopeType(reqEnvelopeType),
 		)
 	}
 
-	var buffer bytes.Buffer
-	err = proto.EncodeEnveloped(wire.Envelope{
+	env := wire.Envelope{
 		Name:  reqBody.MethodName(),
 		Type:  reqEnvelopeType,
 		SeqID: 1, // don't care
 		Value: value,
-	}, &buffer)
-	if err != nil {
-		return nil, nil, errors.RequestBodyEncodeError(&treq, err)
+	}
+
+	var buffer bytes.Buffer
+	if err := proto.EncodeEnveloped(env, &buffer); err != nil {
+		return nil, nil, wire.Envelope{}, errors.RequestBodyEncodeError(&treq, err)
 	}
 
 	treq.Body = &buffer
-	return &treq, proto, nil
+	return &treq, proto, env, nil
+}
+
+// encodeHeaderFrame re-encodes treq.Body with treq.Headers folded into
+// the frame, THeader-style, if proto supports it. It is a no-op for
+// protocols that don't implement protocols.HeaderProtocol, since those
+// already have their headerless encoding from buildTransportRequest.
+//
+// This has to happen after buildTransportRequest because treq.Headers
+// isn't finalized until startSpan and call.WriteToRequest have run.
+func (c thriftClient) encodeHeaderFrame(treq *transport.Request, proto protocol.Protocol, env wire.Envelope) error {
+	hp, ok := proto.(protocols.HeaderProtocol)
+	if !ok || len(treq.Headers.Items()) == 0 {
+		return nil
+	}
+
+	var buffer bytes.Buffer
+	if err := hp.EncodeEnvelopedWithHeaders(env, treq.Headers.Items(), &buffer); err != nil {
+		return err
+	}
+	treq.Body = &buffer
+	return nil
+}
+
+// canonicalRequestBody returns the plain, headerless envelope encoding
+// treq.Body held right after buildTransportRequest produced it. Callers
+// must read this before encodeHeaderFrame can replace treq.Body with a
+// per-call encoding (e.g. one carrying a fresh tracing header), so a
+// cache digest taken over it stays stable across otherwise-identical
+// calls.
+func canonicalRequestBody(treq *transport.Request) []byte {
+	buf, ok := treq.Body.(*bytes.Buffer)
+	if !ok {
+		return nil
+	}
+	return buf.Bytes()
+}
+
+// cacheKeyFor reports the digest Call should use to look up and populate
+// c.cache for treq, and whether treq.Procedure is cacheable at all under
+// c.cachePolicy. canonicalBody must be treq's pre-header-frame envelope
+// encoding (see canonicalRequestBody): hashing the final, post-header
+// encoding instead would fold every header into the digest, including
+// ones like the tracing span context that legitimately differ on every
+// otherwise-identical call, defeating the cache.
+func (c thriftClient) cacheKeyFor(treq *transport.Request, canonicalBody []byte) (key string, ttl time.Duration, cacheable bool) {
+	if c.cache == nil {
+		return "", 0, false
+	}
+	ttl, ok := c.cachePolicy.ttl(treq.Procedure)
+	if !ok {
+		return "", 0, false
+	}
+
+	return cacheKey(treq, c.cachePolicy, canonicalBody), ttl, true
+}
+
+// storeReply populates c.cache with raw under key, unless err rules it
+// out: a nil err always caches, a thriftException only caches when
+// c.cachePolicy.NegativeTTL is configured, and any other error (a
+// transport failure, a malformed frame) is never cached.
+func (c thriftClient) storeReply(ctx context.Context, key string, ttl time.Duration, raw []byte, err error) {
+	switch err.(type) {
+	case nil:
+		c.cache.Set(ctx, key, raw, ttl)
+	case thriftException:
+		if c.cachePolicy.NegativeTTL > 0 {
+			c.cache.Set(ctx, key, raw, c.cachePolicy.NegativeTTL)
+		}
+	}
 }
 
 type thriftException struct {
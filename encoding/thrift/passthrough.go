@@ -0,0 +1,196 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package thrift
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"go.uber.org/thriftrw/protocol"
+	"go.uber.org/yarpc"
+	encodingapi "go.uber.org/yarpc/api/encoding"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/pkg/encoding"
+	"go.uber.org/yarpc/pkg/errors"
+)
+
+// PassThroughRequest carries the inbound transport.Request metadata a
+// PassThroughClient must forward unchanged, alongside the still-framed
+// Thrift request envelope.
+type PassThroughRequest struct {
+	// Procedure is the caller's original, unmodified Procedure. It is
+	// also what gets passed to Director, which may rewrite it for the
+	// forwarded request.
+	Procedure string
+
+	Headers         transport.Headers
+	ShardKey        string
+	RoutingKey      string
+	RoutingDelegate string
+
+	// Body is the already-framed Thrift request envelope.
+	Body io.Reader
+}
+
+// Director resolves the outbound a forwarded Thrift request should be
+// sent on, and may remap its Procedure. procedure is the caller's
+// original, unmodified transport.Request Procedure; method and seqID are
+// recovered by decoding the envelope header, so a Director fronting a
+// single registered procedure for an entire service (the usual
+// grpc-proxy-style registration) can route, and rewrite Procedure, using
+// the real Thrift method name rather than the caller's generic one.
+//
+// Returning an empty newProcedure leaves procedure unchanged.
+//
+// The returned transport.ClientConfig determines the new Caller/Service
+// pair stamped onto the forwarded request.
+type Director func(ctx context.Context, procedure, method string, seqID int32) (cc transport.ClientConfig, newProcedure string, err error)
+
+// PassThroughClient forwards already-serialized Thrift envelopes to
+// another outbound byte for byte, the way mwitkow/grpc-proxy forwards raw
+// gRPC frames. It never re-encodes a request: it decodes each request
+// envelope once with DecodeEnveloped, value included, to read its method
+// name and sequence ID, then discards the decoded value. It is
+// meant for building Thrift-level routers and proxies that carry no
+// generated code for the IDLs they forward.
+type PassThroughClient interface {
+	// Call forwards preq, an already-framed Thrift request envelope, to
+	// the outbound resolved for preq.Procedure, and returns the callee's
+	// raw response envelope unparsed. applicationError reports whether
+	// the callee returned the envelope as a Thrift application error (a
+	// TApplicationException reply); callers must propagate it rather
+	// than treat the reply as an ordinary success.
+	Call(ctx context.Context, preq PassThroughRequest, opts ...yarpc.CallOption) (body io.ReadCloser, applicationError bool, err error)
+
+	// CallOneway forwards preq without waiting for a response body.
+	CallOneway(ctx context.Context, preq PassThroughRequest, opts ...yarpc.CallOption) (transport.Ack, error)
+}
+
+// NewPassThroughClient builds a PassThroughClient that resolves its
+// outbound via director for every call.
+func NewPassThroughClient(director Director, opts ...ClientOption) PassThroughClient {
+	var cc clientConfig
+	for _, opt := range opts {
+		opt.applyClientOption(&cc)
+	}
+
+	p := protocol.Binary
+	if cc.Protocol != nil {
+		p = cc.Protocol
+	}
+
+	return passThroughClient{director: director, p: p}
+}
+
+type passThroughClient struct {
+	director Director
+	p        protocol.Protocol
+}
+
+func (c passThroughClient) Call(ctx context.Context, preq PassThroughRequest, opts ...yarpc.CallOption) (io.ReadCloser, bool, error) {
+	treq, cc, err := c.buildTransportRequest(ctx, preq)
+	if err != nil {
+		return nil, false, err
+	}
+
+	out := cc.GetUnaryOutbound()
+
+	call := encodingapi.NewOutboundCall(encoding.FromOptions(opts)...)
+	ctx, err = call.WriteToRequest(ctx, treq)
+	if err != nil {
+		return nil, false, err
+	}
+
+	tres, err := out.Call(ctx, treq)
+	if err != nil && (tres == nil || !tres.ApplicationError) {
+		return nil, false, err
+	}
+
+	if _, err = call.ReadFromResponse(ctx, tres); err != nil {
+		return nil, false, err
+	}
+
+	return tres.Body, tres.ApplicationError, nil
+}
+
+func (c passThroughClient) CallOneway(ctx context.Context, preq PassThroughRequest, opts ...yarpc.CallOption) (transport.Ack, error) {
+	treq, cc, err := c.buildTransportRequest(ctx, preq)
+	if err != nil {
+		return nil, err
+	}
+
+	out := cc.GetOnewayOutbound()
+
+	call := encodingapi.NewOutboundCall(encoding.FromOptions(opts)...)
+	ctx, err = call.WriteToRequest(ctx, treq)
+	if err != nil {
+		return nil, err
+	}
+
+	return out.CallOneway(ctx, treq)
+}
+
+// buildTransportRequest buffers preq.Body (so it can both be peeked for
+// its envelope header and replayed as the outbound body), decodes the
+// envelope to recover the method name and sequence ID, passes
+// them to c.director along with preq.Procedure to resolve the new
+// outbound and, optionally, a new Procedure, and carries over
+// preq.Headers/ShardKey/RoutingKey/RoutingDelegate so the forwarded
+// request preserves the original's routing and transport headers.
+func (c passThroughClient) buildTransportRequest(ctx context.Context, preq PassThroughRequest) (*transport.Request, transport.ClientConfig, error) {
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(preq.Body); err != nil {
+		return nil, nil, err
+	}
+
+	treq := &transport.Request{Procedure: preq.Procedure}
+
+	// DecodeEnveloped decodes the whole envelope, value included; only
+	// Name and SeqID are used, and buf.Bytes() is forwarded rather than a
+	// re-encoding of the value.
+	env, err := c.p.DecodeEnveloped(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		return nil, nil, errors.RequestBodyDecodeError(treq, err)
+	}
+
+	cc, newProcedure, err := c.director(ctx, preq.Procedure, env.Name, env.SeqID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	procedureName := preq.Procedure
+	if newProcedure != "" {
+		procedureName = newProcedure
+	}
+
+	treq.Caller = cc.Caller()
+	treq.Service = cc.Service()
+	treq.Encoding = Encoding
+	treq.Procedure = procedureName
+	treq.Headers = preq.Headers
+	treq.ShardKey = preq.ShardKey
+	treq.RoutingKey = preq.RoutingKey
+	treq.RoutingDelegate = preq.RoutingDelegate
+	treq.Body = bytes.NewReader(buf.Bytes())
+
+	return treq, cc, nil
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package thrift
+
+import (
+	"context"
+	"io"
+
+	"go.uber.org/yarpc/api/transport"
+)
+
+// NewPassThroughHandler returns a transport.UnaryHandler that forwards
+// every inbound Thrift request to the outbound resolved by director,
+// forwarding the request bytes unchanged. Register it in place of
+// a generated service handler to build a Thrift-level router or proxy:
+//
+//	dispatcher.Register([]transport.Procedure{
+//		{
+//			Name:        "MyService",
+//			HandlerSpec: transport.NewUnaryHandlerSpec(thrift.NewPassThroughHandler(director)),
+//			Encoding:    thrift.Encoding,
+//		},
+//	})
+func NewPassThroughHandler(director Director, opts ...ClientOption) transport.UnaryHandler {
+	return passThroughHandler{client: NewPassThroughClient(director, opts...)}
+}
+
+type passThroughHandler struct {
+	client PassThroughClient
+}
+
+func (h passThroughHandler) Handle(ctx context.Context, req *transport.Request, resw transport.ResponseWriter) error {
+	body, applicationError, err := h.client.Call(ctx, PassThroughRequest{
+		Procedure:       req.Procedure,
+		Headers:         req.Headers,
+		ShardKey:        req.ShardKey,
+		RoutingKey:      req.RoutingKey,
+		RoutingDelegate: req.RoutingDelegate,
+		Body:            req.Body,
+	})
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if applicationError {
+		resw.SetApplicationError()
+	}
+
+	_, err = io.Copy(resw, body)
+	return err
+}
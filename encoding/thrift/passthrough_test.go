@@ -0,0 +1,139 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package thrift
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"go.uber.org/thriftrw/protocol"
+	"go.uber.org/thriftrw/wire"
+	"go.uber.org/yarpc/api/transport"
+)
+
+// fakeClientConfig is a minimal transport.ClientConfig for tests that
+// only need Caller/Service to be stamped onto a forwarded request.
+type fakeClientConfig struct {
+	caller  string
+	service string
+}
+
+func (f fakeClientConfig) Caller() string                             { return f.caller }
+func (f fakeClientConfig) Service() string                            { return f.service }
+func (f fakeClientConfig) GetUnaryOutbound() transport.UnaryOutbound   { return nil }
+func (f fakeClientConfig) GetOnewayOutbound() transport.OnewayOutbound { return nil }
+func (f fakeClientConfig) GetStreamOutbound() transport.StreamOutbound { return nil }
+
+func encodedTestEnvelope(t *testing.T) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	err := protocol.Binary.EncodeEnveloped(wire.Envelope{
+		Name:  "someMethod",
+		Type:  wire.Call,
+		SeqID: 42,
+		Value: wire.NewValueStruct(wire.Struct{}),
+	}, &buf)
+	if err != nil {
+		t.Fatalf("EncodeEnveloped: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestBuildTransportRequestCarriesOverMetadata(t *testing.T) {
+	cc := fakeClientConfig{caller: "caller", service: "callee"}
+	director := func(ctx context.Context, procedure, method string, seqID int32) (transport.ClientConfig, string, error) {
+		return cc, "", nil
+	}
+
+	c := passThroughClient{director: director, p: protocol.Binary}
+
+	headers := transport.Headers{}.With("x-tenant", "acme")
+	preq := PassThroughRequest{
+		Procedure:       "MyService::someMethod",
+		Headers:         headers,
+		ShardKey:        "shard-1",
+		RoutingKey:      "route-1",
+		RoutingDelegate: "delegate-1",
+		Body:            bytes.NewReader(encodedTestEnvelope(t)),
+	}
+
+	treq, gotCC, err := c.buildTransportRequest(context.Background(), preq)
+	if err != nil {
+		t.Fatalf("buildTransportRequest: %v", err)
+	}
+
+	if gotCC != transport.ClientConfig(cc) {
+		t.Fatalf("buildTransportRequest returned a different ClientConfig than the director resolved")
+	}
+	if treq.Caller != cc.caller || treq.Service != cc.service {
+		t.Fatalf("treq Caller/Service = %q/%q; want %q/%q", treq.Caller, treq.Service, cc.caller, cc.service)
+	}
+	if treq.Procedure != preq.Procedure {
+		t.Fatalf("treq.Procedure = %q; want unchanged %q", treq.Procedure, preq.Procedure)
+	}
+	if v, ok := treq.Headers.Get("x-tenant"); !ok || v != "acme" {
+		t.Fatalf("treq.Headers[x-tenant] = %q, %v; want %q, true", v, ok, "acme")
+	}
+	if treq.ShardKey != preq.ShardKey {
+		t.Fatalf("treq.ShardKey = %q; want %q", treq.ShardKey, preq.ShardKey)
+	}
+	if treq.RoutingKey != preq.RoutingKey {
+		t.Fatalf("treq.RoutingKey = %q; want %q", treq.RoutingKey, preq.RoutingKey)
+	}
+	if treq.RoutingDelegate != preq.RoutingDelegate {
+		t.Fatalf("treq.RoutingDelegate = %q; want %q", treq.RoutingDelegate, preq.RoutingDelegate)
+	}
+}
+
+func TestBuildTransportRequestDirectorRewritesProcedure(t *testing.T) {
+	cc := fakeClientConfig{caller: "caller", service: "callee"}
+
+	var gotMethod string
+	var gotSeqID int32
+	director := func(ctx context.Context, procedure, method string, seqID int32) (transport.ClientConfig, string, error) {
+		gotMethod, gotSeqID = method, seqID
+		return cc, "MyService::someMethod", nil
+	}
+
+	c := passThroughClient{director: director, p: protocol.Binary}
+
+	preq := PassThroughRequest{
+		Procedure: "generic",
+		Body:      bytes.NewReader(encodedTestEnvelope(t)),
+	}
+
+	treq, _, err := c.buildTransportRequest(context.Background(), preq)
+	if err != nil {
+		t.Fatalf("buildTransportRequest: %v", err)
+	}
+
+	if gotMethod != "someMethod" {
+		t.Fatalf("director saw method = %q; want %q (decoded from the envelope)", gotMethod, "someMethod")
+	}
+	if gotSeqID != 42 {
+		t.Fatalf("director saw seqID = %d; want %d (decoded from the envelope)", gotSeqID, 42)
+	}
+	if treq.Procedure != "MyService::someMethod" {
+		t.Fatalf("treq.Procedure = %q; want the director's rewritten %q", treq.Procedure, "MyService::someMethod")
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package protocols
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+
+	"go.uber.org/thriftrw/protocol"
+	"go.uber.org/thriftrw/wire"
+)
+
+// headerMagic identifies a frame produced by this package's header
+// protocol. The frame layout (magic, then a length-prefixed header
+// block, then the plain envelope) is loosely inspired by Apache Thrift's
+// THeaderProtocol, but it is a yarpc-internal format: it does not
+// implement THeader's actual transform/info-header wire encoding, so it
+// is not wire-compatible with fbthrift or other THeaderProtocol clients.
+// Both ends of a connection must use this package's HeaderProtocol.
+const headerMagic uint16 = 0x0FFF
+
+// HeaderProtocol is implemented by protocols that can carry transport
+// headers inline in the Thrift frame, THeader-style, in addition to the
+// plain protocol.Protocol envelope encoding.
+//
+// The thrift client's encodeHeaderFrame uses this to merge
+// transport.Request.Headers into the frame, so headers survive gateways
+// that only forward the Thrift body. It does so only for clients built
+// with Enveloped(true) and without Multiplexed(true): otherwise the client
+// wraps the configured protocol in a type that does not implement
+// HeaderProtocol, and its requests carry no inline headers.
+type HeaderProtocol interface {
+	protocol.Protocol
+
+	// EncodeEnvelopedWithHeaders is like protocol.Protocol.EncodeEnveloped
+	// but also writes headers as THeader info-headers ahead of the
+	// envelope.
+	EncodeEnvelopedWithHeaders(e wire.Envelope, headers map[string]string, w io.Writer) error
+
+	// DecodeEnvelopedWithHeaders is like protocol.Protocol.DecodeEnveloped
+	// but also returns any THeader info-headers found ahead of the
+	// envelope.
+	DecodeEnvelopedWithHeaders(r io.Reader) (wire.Envelope, map[string]string, error)
+}
+
+// NewHeaderProtocol wraps inner so that EncodeEnvelopedWithHeaders and
+// DecodeEnvelopedWithHeaders carry per-request transport headers inline in
+// the frame, analogous to Apache Thrift's THeaderProtocol. inner encodes
+// the envelope itself; binary and compact are both valid choices.
+func NewHeaderProtocol(inner protocol.Protocol) HeaderProtocol {
+	return headerProtocol{Protocol: inner}
+}
+
+type headerProtocol struct {
+	protocol.Protocol
+}
+
+func (p headerProtocol) EncodeEnveloped(e wire.Envelope, w io.Writer) error {
+	return p.EncodeEnvelopedWithHeaders(e, nil, w)
+}
+
+func (p headerProtocol) DecodeEnveloped(r io.Reader) (wire.Envelope, error) {
+	e, _, err := p.DecodeEnvelopedWithHeaders(r)
+	return e, err
+}
+
+func (p headerProtocol) EncodeEnvelopedWithHeaders(e wire.Envelope, headers map[string]string, w io.Writer) error {
+	var headerBuf bytes.Buffer
+	if err := writeInfoHeaders(&headerBuf, headers); err != nil {
+		return err
+	}
+
+	var payloadBuf bytes.Buffer
+	if err := p.Protocol.EncodeEnveloped(e, &payloadBuf); err != nil {
+		return err
+	}
+
+	if err := binary.Write(w, binary.BigEndian, headerMagic); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, uint32(headerBuf.Len())); err != nil {
+		return err
+	}
+	if _, err := w.Write(headerBuf.Bytes()); err != nil {
+		return err
+	}
+	_, err := w.Write(payloadBuf.Bytes())
+	return err
+}
+
+func (p headerProtocol) DecodeEnvelopedWithHeaders(r io.Reader) (wire.Envelope, map[string]string, error) {
+	var magic uint16
+	if err := binary.Read(r, binary.BigEndian, &magic); err != nil {
+		return wire.Envelope{}, nil, err
+	}
+	if magic != headerMagic {
+		return wire.Envelope{}, nil, fmt.Errorf("protocols: not a THeader frame (magic %#x)", magic)
+	}
+
+	var headerLen uint32
+	if err := binary.Read(r, binary.BigEndian, &headerLen); err != nil {
+		return wire.Envelope{}, nil, err
+	}
+
+	headerBuf := make([]byte, headerLen)
+	if _, err := io.ReadFull(r, headerBuf); err != nil {
+		return wire.Envelope{}, nil, err
+	}
+	headers, err := readInfoHeaders(bytes.NewReader(headerBuf))
+	if err != nil {
+		return wire.Envelope{}, nil, err
+	}
+
+	e, err := p.Protocol.DecodeEnveloped(r)
+	return e, headers, err
+}
+
+// writeInfoHeaders writes headers as a count-prefixed sequence of
+// length-prefixed key/value string pairs, this package's own header
+// block format (see headerMagic). Keys are written in sorted order so
+// that two calls with identical headers always produce identical bytes,
+// even though map iteration order is randomized.
+func writeInfoHeaders(w io.Writer, headers map[string]string) error {
+	if err := binary.Write(w, binary.BigEndian, uint16(len(headers))); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if err := writeString(w, k); err != nil {
+			return err
+		}
+		if err := writeString(w, headers[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readInfoHeaders(r io.Reader) (map[string]string, error) {
+	var count uint16
+	if err := binary.Read(r, binary.BigEndian, &count); err != nil {
+		return nil, err
+	}
+
+	headers := make(map[string]string, count)
+	for i := uint16(0); i < count; i++ {
+		k, err := readString(r)
+		if err != nil {
+			return nil, err
+		}
+		v, err := readString(r)
+		if err != nil {
+			return nil, err
+		}
+		headers[k] = v
+	}
+	return headers, nil
+}
+
+func writeString(w io.Writer, s string) error {
+	if err := binary.Write(w, binary.BigEndian, uint16(len(s))); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, s)
+	return err
+}
+
+func readString(r io.Reader) (string, error) {
+	var length uint16
+	if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+		return "", err
+	}
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
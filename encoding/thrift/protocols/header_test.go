@@ -0,0 +1,88 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package protocols
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteInfoHeadersIsDeterministic(t *testing.T) {
+	headers := map[string]string{
+		"zebra": "1",
+		"apple": "2",
+		"mango": "3",
+	}
+
+	var first, second bytes.Buffer
+	if err := writeInfoHeaders(&first, headers); err != nil {
+		t.Fatalf("writeInfoHeaders: %v", err)
+	}
+	if err := writeInfoHeaders(&second, headers); err != nil {
+		t.Fatalf("writeInfoHeaders: %v", err)
+	}
+
+	if !bytes.Equal(first.Bytes(), second.Bytes()) {
+		t.Fatalf("writeInfoHeaders produced different bytes for the same headers across calls")
+	}
+}
+
+func TestInfoHeadersRoundTrip(t *testing.T) {
+	headers := map[string]string{
+		"zebra": "1",
+		"apple": "2",
+		"mango": "3",
+	}
+
+	var buf bytes.Buffer
+	if err := writeInfoHeaders(&buf, headers); err != nil {
+		t.Fatalf("writeInfoHeaders: %v", err)
+	}
+
+	got, err := readInfoHeaders(&buf)
+	if err != nil {
+		t.Fatalf("readInfoHeaders: %v", err)
+	}
+
+	if len(got) != len(headers) {
+		t.Fatalf("readInfoHeaders returned %d headers; want %d", len(got), len(headers))
+	}
+	for k, v := range headers {
+		if got[k] != v {
+			t.Errorf("readInfoHeaders[%q] = %q; want %q", k, got[k], v)
+		}
+	}
+}
+
+func TestWriteInfoHeadersEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeInfoHeaders(&buf, nil); err != nil {
+		t.Fatalf("writeInfoHeaders: %v", err)
+	}
+
+	got, err := readInfoHeaders(&buf)
+	if err != nil {
+		t.Fatalf("readInfoHeaders: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("readInfoHeaders returned %d headers; want 0", len(got))
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package protocols lets YARPC Thrift clients and servers select their
+// wire protocol by name (e.g. "binary", "header") instead of importing a
+// specific go.uber.org/thriftrw/protocol package directly. thrift.ProtocolName
+// looks a name up in Default and applies it as a ClientOption.
+//
+// This package has no dispatcher config-loader integration of its own:
+// nothing here parses a YAML protocol field into a ProtocolName call.
+// A config loader wanting to expose "protocol: header" in a dispatcher's
+// YAML would need to look the string up against this package's Default
+// registry itself.
+package protocols
+
+import (
+	"fmt"
+	"sync"
+
+	"go.uber.org/thriftrw/protocol"
+)
+
+// Factory builds a protocol.Protocol on demand. Most factories return a
+// shared, stateless instance.
+type Factory func() protocol.Protocol
+
+// Registry is a named set of Thrift protocol factories. The zero value is
+// an empty Registry ready to use.
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]Factory
+}
+
+// NewRegistry builds an empty Registry. Most callers want the package-level
+// Default registry instead, which already knows about "binary", "compact",
+// "json", and "header".
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]Factory)}
+}
+
+// Register adds factory under name, overwriting any previous registration.
+func (r *Registry) Register(name string, factory Factory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.factories == nil {
+		r.factories = make(map[string]Factory)
+	}
+	r.factories[name] = factory
+}
+
+// Lookup builds the protocol registered under name. It reports false if no
+// factory was registered under that name.
+func (r *Registry) Lookup(name string) (protocol.Protocol, bool) {
+	r.mu.RLock()
+	factory, ok := r.factories[name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	return factory(), true
+}
+
+// MustLookup is like Lookup but panics if name is not registered. It is
+// meant for config loaders that have already validated the protocol name.
+func (r *Registry) MustLookup(name string) protocol.Protocol {
+	p, ok := r.Lookup(name)
+	if !ok {
+		panic(fmt.Sprintf("protocols: no Thrift protocol registered under %q", name))
+	}
+	return p
+}
+
+// Default is the package-level Registry pre-populated with "binary",
+// "compact", "json", and "header".
+var Default = NewRegistry()
+
+func init() {
+	Default.Register("binary", func() protocol.Protocol { return protocol.Binary })
+	Default.Register("compact", func() protocol.Protocol { return protocol.Compact })
+	Default.Register("json", func() protocol.Protocol { return protocol.JSON })
+	Default.Register("header", func() protocol.Protocol { return NewHeaderProtocol(protocol.Binary) })
+}
+
+// Register adds factory under name on the Default registry.
+func Register(name string, factory Factory) {
+	Default.Register(name, factory)
+}
+
+// Lookup builds the protocol registered under name on the Default
+// registry.
+func Lookup(name string) (protocol.Protocol, bool) {
+	return Default.Lookup(name)
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package protocols
+
+import (
+	"testing"
+
+	"go.uber.org/thriftrw/protocol"
+)
+
+func TestRegistryLookup(t *testing.T) {
+	r := NewRegistry()
+	if _, ok := r.Lookup("binary"); ok {
+		t.Fatalf("Lookup on empty registry reported a hit")
+	}
+
+	r.Register("binary", func() protocol.Protocol { return protocol.Binary })
+
+	p, ok := r.Lookup("binary")
+	if !ok {
+		t.Fatalf("Lookup(%q) missed after Register", "binary")
+	}
+	if p != protocol.Binary {
+		t.Fatalf("Lookup(%q) returned a different protocol than registered", "binary")
+	}
+}
+
+func TestRegistryMustLookupPanicsOnUnregisteredName(t *testing.T) {
+	r := NewRegistry()
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("MustLookup did not panic for an unregistered name")
+		}
+	}()
+	r.MustLookup("nonexistent")
+}
+
+func TestDefaultRegistryKnowsBuiltinProtocols(t *testing.T) {
+	for _, name := range []string{"binary", "compact", "json", "header"} {
+		if _, ok := Lookup(name); !ok {
+			t.Errorf("Default registry missing built-in protocol %q", name)
+		}
+	}
+}
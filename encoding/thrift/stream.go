@@ -0,0 +1,200 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package thrift
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"go.uber.org/thriftrw/envelope"
+	"go.uber.org/thriftrw/protocol"
+	"go.uber.org/thriftrw/wire"
+	"go.uber.org/yarpc"
+	encodingapi "go.uber.org/yarpc/api/encoding"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/encoding/thrift/internal"
+	"go.uber.org/yarpc/pkg/encoding"
+	"go.uber.org/yarpc/pkg/errors"
+	"go.uber.org/yarpc/pkg/procedure"
+)
+
+// ClientStream is a long-lived, bidirectional Thrift interaction. Envelopes
+// sent and received on it are length-prefix framed over the underlying
+// transport stream, so any envelope protocol (protocol.Binary,
+// protocol.Compact) works unchanged.
+type ClientStream interface {
+	// Send encodes and writes reqBody as the next envelope on the stream.
+	Send(reqBody envelope.Enveloper) error
+
+	// Recv blocks until the next envelope arrives and decodes its value.
+	// It returns the decoded Reply value, or a thriftException if the
+	// peer sent an Exception envelope.
+	Recv() (wire.Value, error)
+
+	// CloseSend half-closes the stream; no further Send calls are valid,
+	// but Recv may still be called until the peer closes its side.
+	CloseSend() error
+}
+
+// frameLengthSize is the width, in bytes, of the length prefix written
+// before every framed envelope.
+const frameLengthSize = 4
+
+func (c thriftClient) CallStream(ctx context.Context, method string, opts ...yarpc.CallOption) (ClientStream, error) {
+	if !c.streamingEnabled {
+		return nil, fmt.Errorf("thrift: streaming is not enabled on this client; set Config.StreamingEnabled")
+	}
+
+	streamOutbound := c.cc.GetStreamOutbound()
+
+	treq := &transport.Request{
+		Caller:    c.cc.Caller(),
+		Service:   c.cc.Service(),
+		Encoding:  Encoding,
+		Procedure: procedure.ToName(c.thriftService, method),
+	}
+
+	call := encodingapi.NewOutboundCall(encoding.FromOptions(opts)...)
+	ctx, err := call.WriteToRequest(ctx, treq)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := streamOutbound.CallStream(ctx, &transport.StreamRequest{Meta: treq.ToRequestMeta()})
+	if err != nil {
+		return nil, err
+	}
+
+	return &thriftClientStream{
+		ctx:    ctx,
+		treq:   treq,
+		stream: stream,
+		proto:  c.p,
+	}, nil
+}
+
+type thriftClientStream struct {
+	ctx    context.Context
+	treq   *transport.Request
+	stream *transport.ClientStream
+	proto  protocol.Protocol
+
+	seqID int32
+}
+
+func (s *thriftClientStream) Send(reqBody envelope.Enveloper) error {
+	s.seqID++
+
+	value, err := reqBody.ToWire()
+	if err != nil {
+		return err
+	}
+
+	envelopeType := reqBody.EnvelopeType()
+	if envelopeType != wire.Call && envelopeType != wire.OneWay {
+		return errors.RequestBodyEncodeError(s.treq, errUnexpectedEnvelopeType(envelopeType))
+	}
+
+	var buf bytes.Buffer
+	if err := s.proto.EncodeEnveloped(wire.Envelope{
+		Name:  reqBody.MethodName(),
+		Type:  envelopeType,
+		SeqID: s.seqID,
+		Value: value,
+	}, &buf); err != nil {
+		return errors.RequestBodyEncodeError(s.treq, err)
+	}
+
+	framed, err := frameEnvelope(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	return s.stream.SendMessage(s.ctx, &transport.StreamMessage{Body: io.NopCloser(bytes.NewReader(framed))})
+}
+
+func (s *thriftClientStream) Recv() (wire.Value, error) {
+	msg, err := s.stream.ReceiveMessage(s.ctx)
+	if err != nil {
+		return wire.Value{}, err
+	}
+	defer msg.Body.Close()
+
+	raw, err := readFramedEnvelope(msg.Body)
+	if err != nil {
+		return wire.Value{}, errors.ResponseBodyDecodeError(s.treq, err)
+	}
+
+	env, err := s.proto.DecodeEnveloped(bytes.NewReader(raw))
+	if err != nil {
+		return wire.Value{}, errors.ResponseBodyDecodeError(s.treq, err)
+	}
+
+	switch env.Type {
+	case wire.Reply:
+		return env.Value, nil
+	case wire.Exception:
+		var exc internal.TApplicationException
+		if err := exc.FromWire(env.Value); err != nil {
+			return wire.Value{}, errors.ResponseBodyDecodeError(s.treq, err)
+		}
+		return wire.Value{}, thriftException{
+			Service:   s.treq.Service,
+			Procedure: s.treq.Procedure,
+			Reason:    &exc,
+		}
+	default:
+		return wire.Value{}, errors.ResponseBodyDecodeError(s.treq, errUnexpectedEnvelopeType(env.Type))
+	}
+}
+
+func (s *thriftClientStream) CloseSend() error {
+	return s.stream.Close(s.ctx)
+}
+
+// frameEnvelope prefixes payload with its length as a 4-byte big-endian
+// unsigned integer, so the reader of a streamed transport body can tell
+// where one envelope ends and the next begins.
+func frameEnvelope(payload []byte) ([]byte, error) {
+	framed := make([]byte, frameLengthSize+len(payload))
+	binary.BigEndian.PutUint32(framed, uint32(len(payload)))
+	copy(framed[frameLengthSize:], payload)
+	return framed, nil
+}
+
+// readFramedEnvelope reads one length-prefixed envelope written by
+// frameEnvelope.
+func readFramedEnvelope(r io.Reader) ([]byte, error) {
+	var lengthBuf [frameLengthSize]byte
+	if _, err := io.ReadFull(r, lengthBuf[:]); err != nil {
+		return nil, err
+	}
+
+	length := binary.BigEndian.Uint32(lengthBuf[:])
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
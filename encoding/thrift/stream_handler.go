@@ -0,0 +1,101 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package thrift
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"go.uber.org/thriftrw/protocol"
+	"go.uber.org/thriftrw/wire"
+	"go.uber.org/yarpc/api/transport"
+)
+
+// ServerStream is the inbound side of a CallStream interaction. Generated
+// code reads requests off of it with RecvEnvelope and writes responses
+// with SendEnvelope, using the same length-prefixed framing CallStream
+// uses on the client side.
+type ServerStream struct {
+	ctx    context.Context
+	stream *transport.ServerStream
+	proto  protocol.Protocol
+}
+
+// RecvEnvelope blocks until the next framed envelope arrives and decodes
+// it. It returns io.EOF-wrapping errors from the underlying stream
+// unchanged so callers can detect the client closing its send side.
+func (s *ServerStream) RecvEnvelope() (wire.Envelope, error) {
+	msg, err := s.stream.ReceiveMessage(s.ctx)
+	if err != nil {
+		return wire.Envelope{}, err
+	}
+	defer msg.Body.Close()
+
+	raw, err := readFramedEnvelope(msg.Body)
+	if err != nil {
+		return wire.Envelope{}, err
+	}
+	return s.proto.DecodeEnveloped(bytes.NewReader(raw))
+}
+
+// SendEnvelope encodes env and writes it as the next framed message on the
+// stream.
+func (s *ServerStream) SendEnvelope(env wire.Envelope) error {
+	var buf bytes.Buffer
+	if err := s.proto.EncodeEnveloped(env, &buf); err != nil {
+		return err
+	}
+	framed, err := frameEnvelope(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	return s.stream.SendMessage(s.ctx, &transport.StreamMessage{Body: io.NopCloser(bytes.NewReader(framed))})
+}
+
+// StreamHandler is implemented by generated code for Thrift services that
+// declare streaming methods.
+type StreamHandler interface {
+	HandleStream(stream *ServerStream) error
+}
+
+// NewStreamHandler adapts a StreamHandler to transport.StreamHandler,
+// encoding envelopes with p, or with protocol.Binary if p is nil, the same
+// default New uses for unary calls.
+func NewStreamHandler(h StreamHandler, p protocol.Protocol) transport.StreamHandler {
+	if p == nil {
+		p = protocol.Binary
+	}
+	return streamHandler{h: h, p: p}
+}
+
+type streamHandler struct {
+	h StreamHandler
+	p protocol.Protocol
+}
+
+func (h streamHandler) HandleStream(stream *transport.ServerStream) error {
+	return h.h.HandleStream(&ServerStream{
+		ctx:    stream.Context(),
+		stream: stream,
+		proto:  h.p,
+	})
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package thrift
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestFrameEnvelopeRoundTrip(t *testing.T) {
+	payload := []byte("some envelope bytes")
+
+	framed, err := frameEnvelope(payload)
+	if err != nil {
+		t.Fatalf("frameEnvelope: %v", err)
+	}
+
+	got, err := readFramedEnvelope(bytes.NewReader(framed))
+	if err != nil {
+		t.Fatalf("readFramedEnvelope: %v", err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Fatalf("readFramedEnvelope = %q; want %q", got, payload)
+	}
+}
+
+func TestFrameEnvelopeEmptyPayload(t *testing.T) {
+	framed, err := frameEnvelope(nil)
+	if err != nil {
+		t.Fatalf("frameEnvelope: %v", err)
+	}
+
+	got, err := readFramedEnvelope(bytes.NewReader(framed))
+	if err != nil {
+		t.Fatalf("readFramedEnvelope: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("readFramedEnvelope = %q; want empty", got)
+	}
+}
+
+func TestReadFramedEnvelopeMultipleFrames(t *testing.T) {
+	first, err := frameEnvelope([]byte("first"))
+	if err != nil {
+		t.Fatalf("frameEnvelope: %v", err)
+	}
+	second, err := frameEnvelope([]byte("second"))
+	if err != nil {
+		t.Fatalf("frameEnvelope: %v", err)
+	}
+
+	r := bytes.NewReader(append(first, second...))
+
+	got, err := readFramedEnvelope(r)
+	if err != nil {
+		t.Fatalf("readFramedEnvelope: %v", err)
+	}
+	if string(got) != "first" {
+		t.Fatalf("readFramedEnvelope = %q; want %q", got, "first")
+	}
+
+	got, err = readFramedEnvelope(r)
+	if err != nil {
+		t.Fatalf("readFramedEnvelope: %v", err)
+	}
+	if string(got) != "second" {
+		t.Fatalf("readFramedEnvelope = %q; want %q", got, "second")
+	}
+}
+
+func TestReadFramedEnvelopeTruncatedPayload(t *testing.T) {
+	framed, err := frameEnvelope([]byte("hello"))
+	if err != nil {
+		t.Fatalf("frameEnvelope: %v", err)
+	}
+
+	truncated := framed[:len(framed)-1]
+	if _, err := readFramedEnvelope(bytes.NewReader(truncated)); err != io.ErrUnexpectedEOF {
+		t.Fatalf("readFramedEnvelope error = %v; want io.ErrUnexpectedEOF", err)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package thriftcache provides an in-memory implementation of
+// thrift.Cache for thrift.WithCache. It satisfies thrift.Cache
+// structurally, without importing go.uber.org/yarpc/encoding/thrift, so
+// that a Redis- or Memcached-backed thrift.Cache can be built the same
+// way, outside of this module.
+package thriftcache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// LRU is an in-memory, fixed-capacity thrift.Cache. The least recently
+// used entry is evicted whenever Set would otherwise exceed capacity. It
+// is safe for concurrent use.
+type LRU struct {
+	capacity int
+
+	mu      sync.Mutex
+	ll      *list.List // front = most recently used
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	key     string
+	reply   []byte
+	expires time.Time // zero means no expiry
+}
+
+// NewLRU builds an LRU that holds at most capacity entries. capacity
+// must be positive.
+func NewLRU(capacity int) *LRU {
+	if capacity <= 0 {
+		panic("thriftcache: capacity must be positive")
+	}
+	return &LRU{
+		capacity: capacity,
+		ll:       list.New(),
+		entries:  make(map[string]*list.Element, capacity),
+	}
+}
+
+// Get returns the reply cached under key, if present and not expired. An
+// expired entry is evicted and reported as a miss.
+func (c *LRU) Get(_ context.Context, key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if !entry.expires.IsZero() && !time.Now().Before(entry.expires) {
+		c.removeElement(elem)
+		return nil, false
+	}
+
+	c.ll.MoveToFront(elem)
+	return entry.reply, true
+}
+
+// Set stores reply under key for ttl, evicting the least recently used
+// entry first if the cache is at capacity. A zero ttl means reply never
+// expires on its own.
+func (c *LRU) Set(_ context.Context, key string, reply []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expires time.Time
+	if ttl > 0 {
+		expires = time.Now().Add(ttl)
+	}
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*lruEntry).reply = reply
+		elem.Value.(*lruEntry).expires = expires
+		c.ll.MoveToFront(elem)
+		return
+	}
+
+	elem := c.ll.PushFront(&lruEntry{key: key, reply: reply, expires: expires})
+	c.entries[key] = elem
+
+	if c.ll.Len() > c.capacity {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+func (c *LRU) removeElement(elem *list.Element) {
+	c.ll.Remove(elem)
+	delete(c.entries, elem.Value.(*lruEntry).key)
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package thriftcache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLRUGetSet(t *testing.T) {
+	c := NewLRU(2)
+	ctx := context.Background()
+
+	if _, ok := c.Get(ctx, "a"); ok {
+		t.Fatalf("Get on empty cache reported a hit")
+	}
+
+	c.Set(ctx, "a", []byte("1"), 0)
+	reply, ok := c.Get(ctx, "a")
+	if !ok || string(reply) != "1" {
+		t.Fatalf("Get(%q) = %q, %v; want %q, true", "a", reply, ok, "1")
+	}
+}
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRU(2)
+	ctx := context.Background()
+
+	c.Set(ctx, "a", []byte("1"), 0)
+	c.Set(ctx, "b", []byte("2"), 0)
+
+	// Touch "a" so "b" becomes the least recently used entry.
+	if _, ok := c.Get(ctx, "a"); !ok {
+		t.Fatalf("Get(%q) missed", "a")
+	}
+
+	c.Set(ctx, "c", []byte("3"), 0)
+
+	if _, ok := c.Get(ctx, "b"); ok {
+		t.Fatalf("Get(%q) hit after eviction", "b")
+	}
+	if _, ok := c.Get(ctx, "a"); !ok {
+		t.Fatalf("Get(%q) missed; want still resident", "a")
+	}
+	if _, ok := c.Get(ctx, "c"); !ok {
+		t.Fatalf("Get(%q) missed; want resident", "c")
+	}
+}
+
+func TestLRUExpiry(t *testing.T) {
+	c := NewLRU(2)
+	ctx := context.Background()
+
+	c.Set(ctx, "a", []byte("1"), time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	if _, ok := c.Get(ctx, "a"); ok {
+		t.Fatalf("Get(%q) hit after ttl elapsed", "a")
+	}
+}
+
+func TestLRUSetOverwritesExistingKey(t *testing.T) {
+	c := NewLRU(2)
+	ctx := context.Background()
+
+	c.Set(ctx, "a", []byte("1"), 0)
+	c.Set(ctx, "a", []byte("2"), 0)
+
+	reply, ok := c.Get(ctx, "a")
+	if !ok || string(reply) != "2" {
+		t.Fatalf("Get(%q) = %q, %v; want %q, true", "a", reply, ok, "2")
+	}
+}
+
+func TestNewLRUPanicsOnNonPositiveCapacity(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("NewLRU(0) did not panic")
+		}
+	}()
+	NewLRU(0)
+}
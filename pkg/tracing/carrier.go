@@ -0,0 +1,88 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tracing
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// binarySpanContextVersion is the only version of the binary traceparent
+// encoding this package understands, matching the W3C Trace Context
+// "00" version byte.
+const binarySpanContextVersion = 0x00
+
+// binarySpanContextSize is version(1) + trace ID(16) + span ID(8) + flags(1).
+const binarySpanContextSize = 26
+
+// MarshalBinarySpanContext encodes sc as a fixed-size byte slice. It is
+// the codec InjectSpanContextHeader uses to fit a span context into a
+// single string header value (base64-encoded), and is exported standalone
+// for transports that carry a dedicated binary field for trace
+// propagation instead of string headers.
+//
+// The layout mirrors the W3C Trace Context traceparent header:
+// version, trace ID, span ID and trace flags, concatenated as raw bytes
+// rather than hex-encoded text.
+func MarshalBinarySpanContext(sc trace.SpanContext) []byte {
+	if !sc.IsValid() {
+		return nil
+	}
+
+	buf := make([]byte, binarySpanContextSize)
+	buf[0] = binarySpanContextVersion
+	traceID := sc.TraceID()
+	spanID := sc.SpanID()
+	copy(buf[1:17], traceID[:])
+	copy(buf[17:25], spanID[:])
+	buf[25] = byte(sc.TraceFlags())
+	return buf
+}
+
+// UnmarshalBinarySpanContext decodes a byte slice produced by
+// MarshalBinarySpanContext back into a trace.SpanContext that can be used
+// to start a child span on the receiving side of an RPC.
+func UnmarshalBinarySpanContext(b []byte) (trace.SpanContext, error) {
+	if len(b) == 0 {
+		return trace.SpanContext{}, nil
+	}
+	if len(b) != binarySpanContextSize {
+		return trace.SpanContext{}, fmt.Errorf(
+			"tracing: invalid binary span context length %d, expected %d", len(b), binarySpanContextSize)
+	}
+	if b[0] != binarySpanContextVersion {
+		return trace.SpanContext{}, fmt.Errorf(
+			"tracing: unsupported binary span context version %#x", b[0])
+	}
+
+	var traceID trace.TraceID
+	var spanID trace.SpanID
+	copy(traceID[:], b[1:17])
+	copy(spanID[:], b[17:25])
+
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.TraceFlags(b[25]),
+		Remote:     true,
+	}), nil
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tracing
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func testSpanContext(t *testing.T) trace.SpanContext {
+	t.Helper()
+
+	traceID, err := trace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	if err != nil {
+		t.Fatalf("TraceIDFromHex: %v", err)
+	}
+	spanID, err := trace.SpanIDFromHex("0102030405060708")
+	if err != nil {
+		t.Fatalf("SpanIDFromHex: %v", err)
+	}
+
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	})
+}
+
+func TestMarshalUnmarshalBinarySpanContextRoundTrip(t *testing.T) {
+	sc := testSpanContext(t)
+
+	got, err := UnmarshalBinarySpanContext(MarshalBinarySpanContext(sc))
+	if err != nil {
+		t.Fatalf("UnmarshalBinarySpanContext: %v", err)
+	}
+
+	if got.TraceID() != sc.TraceID() {
+		t.Errorf("TraceID = %s; want %s", got.TraceID(), sc.TraceID())
+	}
+	if got.SpanID() != sc.SpanID() {
+		t.Errorf("SpanID = %s; want %s", got.SpanID(), sc.SpanID())
+	}
+	if got.TraceFlags() != sc.TraceFlags() {
+		t.Errorf("TraceFlags = %v; want %v", got.TraceFlags(), sc.TraceFlags())
+	}
+	if !got.IsRemote() {
+		t.Errorf("IsRemote() = false; want true for a decoded span context")
+	}
+}
+
+func TestMarshalBinarySpanContextInvalid(t *testing.T) {
+	if b := MarshalBinarySpanContext(trace.SpanContext{}); b != nil {
+		t.Fatalf("MarshalBinarySpanContext(invalid) = %v; want nil", b)
+	}
+}
+
+func TestUnmarshalBinarySpanContextEmpty(t *testing.T) {
+	got, err := UnmarshalBinarySpanContext(nil)
+	if err != nil {
+		t.Fatalf("UnmarshalBinarySpanContext(nil): %v", err)
+	}
+	if got.IsValid() {
+		t.Fatalf("UnmarshalBinarySpanContext(nil) = %v; want an invalid span context", got)
+	}
+}
+
+func TestUnmarshalBinarySpanContextWrongLength(t *testing.T) {
+	if _, err := UnmarshalBinarySpanContext([]byte{0x00, 0x01}); err == nil {
+		t.Fatalf("UnmarshalBinarySpanContext with wrong length did not error")
+	}
+}
+
+func TestUnmarshalBinarySpanContextUnsupportedVersion(t *testing.T) {
+	b := MarshalBinarySpanContext(testSpanContext(t))
+	b[0] = 0xFF
+
+	if _, err := UnmarshalBinarySpanContext(b); err == nil {
+		t.Fatalf("UnmarshalBinarySpanContext with unsupported version did not error")
+	}
+}
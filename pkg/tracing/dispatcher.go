@@ -0,0 +1,70 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tracing
+
+import (
+	"context"
+
+	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/api/transport"
+)
+
+// Register installs tracer as inbound tracing middleware on cfg, composing
+// with whatever inbound middleware cfg already carries. system is recorded
+// as the rpc.system attribute on every inbound span (e.g. "thrift"); an
+// encoding normally exposes its own registration helper that calls this
+// with its name rather than ask dispatcher authors to pass it by hand; see
+// go.uber.org/yarpc/encoding/thrift's RegisterTracing for an example. Call
+// it before passing cfg to yarpc.NewDispatcher:
+//
+//	cfg := yarpc.Config{Name: "my-service"}
+//	tracing.Register(&cfg, tracer, "thrift")
+//	dispatcher := yarpc.NewDispatcher(cfg)
+//
+// Register touches only the Config value passed to it, so tracers are
+// never shared through global state.
+func Register(cfg *yarpc.Config, tracer *Tracer, system string) {
+	next := UnaryInbound(tracer, system)
+	if existing := cfg.InboundMiddleware.Unary; existing != nil {
+		next = chainedUnaryInbound{first: existing, second: next}
+	}
+	cfg.InboundMiddleware.Unary = next
+}
+
+// chainedUnaryInbound runs first and second in sequence around the
+// terminal handler, outermost first.
+type chainedUnaryInbound struct {
+	first  transport.UnaryInboundMiddleware
+	second transport.UnaryInboundMiddleware
+}
+
+func (c chainedUnaryInbound) Handle(ctx context.Context, req *transport.Request, resw transport.ResponseWriter, h transport.UnaryHandler) error {
+	return c.first.Handle(ctx, req, resw, wrappedUnaryHandler{middleware: c.second, next: h})
+}
+
+type wrappedUnaryHandler struct {
+	middleware transport.UnaryInboundMiddleware
+	next       transport.UnaryHandler
+}
+
+func (w wrappedUnaryHandler) Handle(ctx context.Context, req *transport.Request, resw transport.ResponseWriter) error {
+	return w.middleware.Handle(ctx, req, resw, w.next)
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tracing
+
+import (
+	"context"
+	"encoding/base64"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/yarpc/api/transport"
+)
+
+// SpanContextHeader is the transport header under which the binary W3C
+// traceparent produced by MarshalBinarySpanContext is propagated, for
+// transports and encodings that only carry string headers.
+//
+// Deliberate deviation: the original request for this package asked to
+// propagate the span context via serialize/internal's existing
+// internal.RPC.spanContext binary field, so wire-level propagation would
+// keep working across encodings that don't carry arbitrary string
+// headers. This repo slice has no generated Go struct for internal.RPC —
+// serialize/internal only ships the IDL's thriftreflect metadata
+// (idl.go/internal.thrift), not the accessor code a real Thrift compiler
+// would produce for that field — so there is nothing in this tree to
+// assign spanContext on. SpanContextHeader is a yarpc transport header
+// instead, which every encoding here already threads through
+// transport.Request.Headers; it covers the same propagation need for
+// every transport this package currently runs against, but is not the
+// wire-compatible internal.RPC.spanContext field the request named, and
+// would need revisiting once that generated code exists.
+const SpanContextHeader = "rpc-span-context-bin"
+
+// UnaryInbound returns a transport.UnaryInboundMiddleware that starts a
+// server span, parented off of the caller's propagated span context when
+// one is present, for every inbound unary request. system is recorded as
+// the span's rpc.system attribute (e.g. "thrift"); encodings should
+// expose their own registration helper that calls this with their name
+// rather than require callers to pass it by hand.
+//
+// Encodings that cannot thread a Tracer through a ClientOption-equivalent
+// registration (raw transport.Handler registrations, for example) can
+// install this directly on the dispatcher instead.
+func UnaryInbound(tracer *Tracer, system string) transport.UnaryInboundMiddleware {
+	return unaryInboundMiddleware{tracer: tracer, system: system}
+}
+
+type unaryInboundMiddleware struct {
+	tracer *Tracer
+	system string
+}
+
+func (m unaryInboundMiddleware) Handle(ctx context.Context, req *transport.Request, resw transport.ResponseWriter, h transport.UnaryHandler) error {
+	attrs := CallAttributes{
+		System:      m.system,
+		Service:     req.Service,
+		Method:      req.Procedure,
+		PeerService: req.Caller,
+	}
+
+	parent, _ := spanContextFromHeaders(req.Headers)
+
+	ctx, span := m.tracer.StartInboundSpan(ctx, parent, attrs)
+	defer span.End()
+
+	if err := h.Handle(ctx, req, resw); err != nil {
+		RecordError(span, err)
+		return err
+	}
+	return nil
+}
+
+// spanContextFromHeaders extracts and decodes the span context carried in
+// req.Headers under SpanContextHeader, if any.
+func spanContextFromHeaders(headers transport.Headers) (trace.SpanContext, error) {
+	encoded, ok := headers.Get(SpanContextHeader)
+	if !ok {
+		return trace.SpanContext{}, nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return trace.SpanContext{}, err
+	}
+	return UnmarshalBinarySpanContext(raw)
+}
+
+// InjectSpanContextHeader returns the transport header pair that carries
+// sc so the callee can recover it via spanContextFromHeaders.
+func InjectSpanContextHeader(headers transport.Headers, sc trace.SpanContext) transport.Headers {
+	b := MarshalBinarySpanContext(sc)
+	if len(b) == 0 {
+		return headers
+	}
+	return headers.With(SpanContextHeader, base64.StdEncoding.EncodeToString(b))
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tracing
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/yarpc/api/transport"
+)
+
+// TestSpanContextHeaderRoundTrip covers the extraction half of inbound
+// span parenting: UnaryInbound parents a new server span off of whatever
+// spanContextFromHeaders recovers from req.Headers, so the two must agree
+// on what InjectSpanContextHeader put there.
+func TestSpanContextHeaderRoundTrip(t *testing.T) {
+	sc := testSpanContext(t)
+
+	headers := InjectSpanContextHeader(transport.Headers{}, sc)
+
+	got, err := spanContextFromHeaders(headers)
+	if err != nil {
+		t.Fatalf("spanContextFromHeaders: %v", err)
+	}
+	if got.TraceID() != sc.TraceID() {
+		t.Errorf("TraceID = %s; want %s", got.TraceID(), sc.TraceID())
+	}
+	if got.SpanID() != sc.SpanID() {
+		t.Errorf("SpanID = %s; want %s", got.SpanID(), sc.SpanID())
+	}
+}
+
+func TestSpanContextFromHeadersMissing(t *testing.T) {
+	got, err := spanContextFromHeaders(transport.Headers{})
+	if err != nil {
+		t.Fatalf("spanContextFromHeaders: %v", err)
+	}
+	if got.IsValid() {
+		t.Fatalf("spanContextFromHeaders with no header = %v; want an invalid span context", got)
+	}
+}
+
+func TestSpanContextFromHeadersInvalidBase64(t *testing.T) {
+	headers := transport.Headers{}.With(SpanContextHeader, "not valid base64!!")
+
+	if _, err := spanContextFromHeaders(headers); err == nil {
+		t.Fatalf("spanContextFromHeaders with invalid base64 did not error")
+	}
+}
+
+func TestInjectSpanContextHeaderInvalidSpanContextIsNoOp(t *testing.T) {
+	headers := InjectSpanContextHeader(transport.Headers{}, trace.SpanContext{})
+	if _, ok := headers.Get(SpanContextHeader); ok {
+		t.Fatalf("InjectSpanContextHeader set %s for an invalid span context", SpanContextHeader)
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CallAttributes carries the RPC-level attributes recorded on a span for a
+// single encoding call. Encodings populate it with whatever they know
+// about the request; fields left at their zero value are omitted.
+type CallAttributes struct {
+	// System identifies the encoding, e.g. "thrift".
+	System string
+	// Service is the service being invoked: the Thrift service name on
+	// outbound Thrift spans, the transport.Request.Service on inbound
+	// spans.
+	Service string
+	// Method is the RPC method name on outbound spans; inbound spans
+	// record the full transport.Request.Procedure.
+	Method string
+	// PeerService names the remote party: the callee's YARPC service on
+	// outbound spans, the transport.Request.Caller on inbound spans.
+	PeerService string
+	// Envelope is the Thrift envelope type ("Call" or "OneWay").
+	Envelope string
+}
+
+func (a CallAttributes) attributes() []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, 5)
+	if a.System != "" {
+		attrs = append(attrs, attribute.String("rpc.system", a.System))
+	}
+	if a.Service != "" {
+		attrs = append(attrs, attribute.String("rpc.service", a.Service))
+	}
+	if a.Method != "" {
+		attrs = append(attrs, attribute.String("rpc.method", a.Method))
+	}
+	if a.PeerService != "" {
+		attrs = append(attrs, attribute.String("peer.service", a.PeerService))
+	}
+	if a.Envelope != "" {
+		attrs = append(attrs, attribute.String("rpc.thrift.envelope_type", a.Envelope))
+	}
+	return attrs
+}
+
+// StartOutboundSpan starts a client span for an outbound call described by
+// attrs and returns the context that carries it. Callers must end the
+// returned span.
+func (t *Tracer) StartOutboundSpan(ctx context.Context, attrs CallAttributes) (context.Context, trace.Span) {
+	return t.tracer.Start(ctx, attrs.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs.attributes()...),
+	)
+}
+
+// StartInboundSpan starts a server span for an inbound call described by
+// attrs, resuming the given remote span context (if valid) as its parent.
+func (t *Tracer) StartInboundSpan(ctx context.Context, parent trace.SpanContext, attrs CallAttributes) (context.Context, trace.Span) {
+	if parent.IsValid() {
+		ctx = trace.ContextWithRemoteSpanContext(ctx, parent)
+	}
+	return t.tracer.Start(ctx, attrs.Method,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attrs.attributes()...),
+	)
+}
+
+// RecordError marks span as failed and attaches err to it. Encodings call
+// this for application-level exceptions (such as a Thrift
+// TApplicationException) that the transport itself does not treat as a
+// transport error.
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package tracing provides an OpenTelemetry-based distributed tracing
+// subsystem for YARPC encodings and transports that export spans via OTLP.
+//
+// It is deliberately encoding-agnostic: encodings (such as encoding/thrift)
+// depend on this package to start and propagate spans, rather than the
+// other way around, so that no encoding carries any tracing-specific global
+// state.
+//
+// This package requires go.opentelemetry.io/otel and its otlptrace/sdk/
+// semconv subpackages at a version providing the semconv/v1.17.0 package
+// (otel >= v1.16.0); pin these in the module's go.mod/go.sum alongside the
+// rest of its dependencies.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Transport selects the OTLP wire protocol used to export spans.
+type Transport string
+
+const (
+	// TransportGRPC exports spans over OTLP/gRPC. This is the default.
+	TransportGRPC Transport = "grpc"
+
+	// TransportHTTP exports spans over OTLP/HTTP.
+	TransportHTTP Transport = "http"
+)
+
+// Config describes how to construct a Tracer. Its fields carry yaml tags
+// so a dispatcher config loader can unmarshal a block shaped like:
+//
+//	tracing:
+//	  serviceName: my-service
+//	  transport: grpc
+//	  endpoint: otel-collector:4317
+//	  insecure: true
+//
+// into it, but this package does not itself read a dispatcher's YAML
+// configuration or call New from one: there is no config-loader
+// integration here, only the struct shape a loader could target. Callers
+// build a Config and call New directly.
+type Config struct {
+	// ServiceName is recorded as the service.name resource attribute on
+	// every span. Required.
+	ServiceName string `yaml:"serviceName"`
+
+	// Transport selects the OTLP exporter. Defaults to TransportGRPC.
+	Transport Transport `yaml:"transport"`
+
+	// Endpoint is the host:port of the OTLP collector, for either
+	// transport. Required.
+	Endpoint string `yaml:"endpoint"`
+
+	// Insecure disables TLS when dialing the collector. Defaults to false.
+	Insecure bool `yaml:"insecure"`
+
+	// Headers are sent with every OTLP export request, e.g. for
+	// collector authentication.
+	Headers map[string]string `yaml:"headers"`
+}
+
+// Tracer wraps an OpenTelemetry tracer along with the TracerProvider that
+// owns it, so that callers can flush or shut it down at process exit.
+type Tracer struct {
+	tracer   trace.Tracer
+	provider *sdktrace.TracerProvider
+}
+
+// New builds a Tracer that exports spans to an OTLP collector as described
+// by cfg.
+func New(ctx context.Context, cfg Config) (*Tracer, error) {
+	if cfg.ServiceName == "" {
+		return nil, fmt.Errorf("tracing: ServiceName is required")
+	}
+	if cfg.Endpoint == "" {
+		return nil, fmt.Errorf("tracing: Endpoint is required")
+	}
+
+	exporter, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("tracing: failed to build OTLP exporter: %w", err)
+	}
+
+	res, err := resource.New(ctx, resource.WithAttributes(
+		semconv.ServiceName(cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("tracing: failed to build resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+
+	return &Tracer{
+		tracer:   provider.Tracer("go.uber.org/yarpc/pkg/tracing"),
+		provider: provider,
+	}, nil
+}
+
+func newExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, error) {
+	switch cfg.Transport {
+	case "", TransportGRPC:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		if len(cfg.Headers) > 0 {
+			opts = append(opts, otlptracegrpc.WithHeaders(cfg.Headers))
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	case TransportHTTP:
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		if len(cfg.Headers) > 0 {
+			opts = append(opts, otlptracehttp.WithHeaders(cfg.Headers))
+		}
+		return otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("tracing: unknown transport %q", cfg.Transport)
+	}
+}
+
+// Tracer returns the underlying OpenTelemetry tracer.
+func (t *Tracer) Tracer() trace.Tracer {
+	return t.tracer
+}
+
+// Shutdown flushes any buffered spans and releases the exporter's
+// resources. Callers should invoke it when the dispatcher stops.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	return t.provider.Shutdown(ctx)
+}